@@ -0,0 +1,115 @@
+package core
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// urlPattern matches bare URLs in plain text. The authority/domain segment
+// only consumes a "." when another domain character follows it, so a
+// sentence-ending period is never pulled into the match; the path segment
+// keeps the original rule that trailing sentence punctuation (".", ",",
+// "!", "?", ";", ":") is allowed mid-path but not swallowed at the very end.
+var urlPattern = regexp.MustCompile(
+	`(?i)\b(https?|s?ftps?|file|gopher|mailto|nntp)://` +
+		`([a-zA-Z0-9_@\-\[\]:]+(?:\.[a-zA-Z0-9_@\-\[\]:]+)*)` +
+		`(/[a-zA-Z0-9$'()*+&#=@~_/\-\[\]%]*(?:[.,:;?!]+[a-zA-Z0-9$'()*+&#=@~_/\-\[\]%]+)*)?`)
+
+// rfcPattern matches "RFC 1234" or "RFC 1234, Section 5.2" style references.
+var rfcPattern = regexp.MustCompile(`(?i)\bRFC\s+(\d{3,5})(,?\s+[Ss]ection\s+(\d+(\.\d+)*))?`)
+
+// emailPattern matches a plain email address.
+var emailPattern = regexp.MustCompile(`\b[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}\b`)
+
+// autoLinkMatch is one span of text identified as linkifiable, carrying its
+// already-rendered replacement.
+type autoLinkMatch struct {
+	start, end int
+	// priority breaks ties between matches that start at the same offset: a
+	// lower value wins. Among overlapping matches with different starts, the
+	// one that starts first wins (e.g. a URL over the email address embedded
+	// in its query string).
+	priority int
+	replaced string
+}
+
+// autoLinkify wraps bare URLs, RFC references and email addresses found in
+// plain text into links rendered by the parser's Renderer (Markdown, Org or
+// MediaWiki syntax), according to the AutoLinkify sub-flags in
+// OutputConfig. It is a no-op unless at least one sub-flag is enabled.
+//
+// All three patterns are matched against the original, unmodified text and
+// merged into a single set of non-overlapping spans before any replacement
+// happens, rather than running each ReplaceAllStringFunc pass over the
+// previous pass's output: otherwise a later pass (e.g. Emails) re-scans text
+// an earlier pass already wrapped in a link (e.g. the email embedded in a
+// URL's query string) and nests a second link inside it.
+func (p *Parser) autoLinkify(text string) string {
+	cfg := OutputConfig{}
+	if output := p.ctx.Value("output"); output != nil {
+		cfg = output.(OutputConfig)
+	}
+	if !cfg.AutoLinkify.URLs && !cfg.AutoLinkify.RFCs && !cfg.AutoLinkify.Emails {
+		return text
+	}
+
+	var matches []autoLinkMatch
+	if cfg.AutoLinkify.URLs {
+		for _, loc := range urlPattern.FindAllStringIndex(text, -1) {
+			match := text[loc[0]:loc[1]]
+			matches = append(matches, autoLinkMatch{
+				start: loc[0], end: loc[1], priority: 0,
+				replaced: p.renderer.Link(match, match),
+			})
+		}
+	}
+	if cfg.AutoLinkify.RFCs {
+		for _, loc := range rfcPattern.FindAllStringSubmatchIndex(text, -1) {
+			match := text[loc[0]:loc[1]]
+			num := text[loc[2]:loc[3]]
+			url := "https://rfc-editor.org/rfc/rfc" + num + ".html"
+			if loc[6] != -1 {
+				url += "#section-" + text[loc[6]:loc[7]]
+			}
+			matches = append(matches, autoLinkMatch{
+				start: loc[0], end: loc[1], priority: 1,
+				replaced: p.renderer.Link(match, url),
+			})
+		}
+	}
+	if cfg.AutoLinkify.Emails {
+		for _, loc := range emailPattern.FindAllStringIndex(text, -1) {
+			match := text[loc[0]:loc[1]]
+			matches = append(matches, autoLinkMatch{
+				start: loc[0], end: loc[1], priority: 2,
+				replaced: p.renderer.Link(match, "mailto:"+match),
+			})
+		}
+	}
+	if len(matches) == 0 {
+		return text
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].start != matches[j].start {
+			return matches[i].start < matches[j].start
+		}
+		return matches[i].priority < matches[j].priority
+	})
+
+	buf := new(strings.Builder)
+	pos := 0
+	for _, m := range matches {
+		if m.start < pos {
+			// Overlaps a match already emitted, e.g. an
+			// email address inside a URL that was already linkified.
+			continue
+		}
+		buf.WriteString(text[pos:m.start])
+		buf.WriteString(m.replaced)
+		pos = m.end
+	}
+	buf.WriteString(text[pos:])
+	return buf.String()
+}
@@ -0,0 +1,19 @@
+package core
+
+import "testing"
+
+func TestUrlPatternDoesNotSwallowSentencePeriod(t *testing.T) {
+	got := urlPattern.FindString("Visit https://example.com. Thanks")
+	want := "https://example.com"
+	if got != want {
+		t.Errorf("urlPattern.FindString() = %q, want %q", got, want)
+	}
+}
+
+func TestUrlPatternKeepsMidPathPunctuation(t *testing.T) {
+	got := urlPattern.FindString("See https://example.com/a.b,c for details")
+	want := "https://example.com/a.b,c"
+	if got != want {
+		t.Errorf("urlPattern.FindString() = %q, want %q", got, want)
+	}
+}
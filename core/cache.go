@@ -0,0 +1,184 @@
+package core
+
+import (
+	"bufio"
+	"container/list"
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultCacheFractionOfMemory is the share of total system memory the
+// default cache ceiling uses when OutputConfig.MemoryLimitMB is unset.
+const defaultCacheFractionOfMemory = 0.05
+
+// defaultCacheLimitMB is the fallback ceiling used when the system memory
+// size cannot be determined (e.g. non-Linux platforms).
+const defaultCacheLimitMB = 128
+
+// CacheStats reports cumulative hit/miss/eviction counters for a Cache.
+type CacheStats struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+}
+
+// Cache is a size-bounded, least-recently-used byte-value store shared by the
+// chapter-fetch and image-download paths.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte)
+	Stats() CacheStats
+}
+
+type lruEntry struct {
+	key   string
+	value []byte
+}
+
+// lruCache is the default Cache implementation. It evicts the
+// least-recently-used entry whenever adding a new one would exceed
+// maxBytes.
+type lruCache struct {
+	mu       sync.Mutex
+	ll       *list.List
+	index    map[string]*list.Element
+	maxBytes int64
+	curBytes int64
+	stats    CacheStats
+}
+
+// NewLRUCache returns a Cache bounded to maxBytes of stored entry size.
+func NewLRUCache(maxBytes int64) Cache {
+	return &lruCache{
+		ll:       list.New(),
+		index:    make(map[string]*list.Element),
+		maxBytes: maxBytes,
+	}
+}
+
+func (c *lruCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.index[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	c.ll.MoveToFront(elem)
+	c.stats.Hits++
+	return elem.Value.(*lruEntry).value, true
+}
+
+func (c *lruCache) Set(key string, value []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.index[key]; ok {
+		c.curBytes += int64(len(value)) - int64(len(elem.Value.(*lruEntry).value))
+		elem.Value.(*lruEntry).value = value
+		c.ll.MoveToFront(elem)
+	} else {
+		elem := c.ll.PushFront(&lruEntry{key: key, value: value})
+		c.index[key] = elem
+		c.curBytes += int64(len(value))
+	}
+
+	for c.curBytes > c.maxBytes && c.ll.Len() > 0 {
+		oldest := c.ll.Back()
+		if oldest == nil {
+			break
+		}
+		c.ll.Remove(oldest)
+		entry := oldest.Value.(*lruEntry)
+		delete(c.index, entry.key)
+		c.curBytes -= int64(len(entry.value))
+		c.stats.Evictions++
+	}
+}
+
+func (c *lruCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// defaultCacheLimitBytes picks a ceiling for a cache that has no explicit
+// OutputConfig.MemoryLimitMB, using a small fraction of total system memory
+// where that can be determined and a conservative constant otherwise.
+func defaultCacheLimitBytes() int64 {
+	if total, ok := totalSystemMemoryBytes(); ok {
+		return int64(float64(total) * defaultCacheFractionOfMemory)
+	}
+	return defaultCacheLimitMB * 1024 * 1024
+}
+
+// totalSystemMemoryBytes reads MemTotal from /proc/meminfo. It only works on
+// Linux; callers must treat a false ok as "unknown" and fall back.
+func totalSystemMemoryBytes() (int64, bool) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "MemTotal:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return 0, false
+		}
+		kb, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return kb * 1024, true
+	}
+	return 0, false
+}
+
+var (
+	docsCacheOnce sync.Once
+	docsCache     Cache
+
+	imageCacheOnce sync.Once
+	imageCache     Cache
+)
+
+// getDocsCache returns the process-wide cache used to memoize GetDocsContent
+// results, created lazily from the first context it sees.
+func getDocsCache(ctx context.Context) Cache {
+	docsCacheOnce.Do(func() {
+		docsCache = NewLRUCache(cacheLimitBytesFromContext(ctx))
+	})
+	return docsCache
+}
+
+// getImageCache returns the process-wide cache used to memoize downloaded
+// image bytes keyed by ImgToken.
+func getImageCache(ctx context.Context) Cache {
+	imageCacheOnce.Do(func() {
+		imageCache = NewLRUCache(cacheLimitBytesFromContext(ctx))
+	})
+	return imageCache
+}
+
+// cacheLimitBytesFromContext resolves OutputConfig.MemoryLimitMB off the
+// "output" context value, falling back to defaultCacheLimitBytes when unset.
+func cacheLimitBytesFromContext(ctx context.Context) int64 {
+	if ctx != nil {
+		if output := ctx.Value("output"); output != nil {
+			if cfg, ok := output.(OutputConfig); ok && cfg.MemoryLimitMB > 0 {
+				return int64(cfg.MemoryLimitMB) * 1024 * 1024
+			}
+		}
+	}
+	return defaultCacheLimitBytes()
+}
@@ -0,0 +1,52 @@
+package core
+
+import "testing"
+
+func TestLRUCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRUCache(10)
+
+	c.Set("a", []byte("12345")) // 5 bytes, curBytes=5
+	c.Set("b", []byte("12345")) // 5 bytes, curBytes=10
+
+	if _, ok := c.Get("a"); !ok {
+		t.Fatalf("expected a to still be cached")
+	}
+	// "a" is now most-recently-used; "b" is least-recently-used.
+
+	c.Set("c", []byte("12345")) // pushes curBytes to 15, over maxBytes=10
+
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("expected b to have been evicted as least-recently-used")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Errorf("expected a to still be cached")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Errorf("expected c to still be cached")
+	}
+
+	stats := c.Stats()
+	if stats.Evictions != 1 {
+		t.Errorf("Evictions = %d, want 1", stats.Evictions)
+	}
+}
+
+func TestLRUCacheStats(t *testing.T) {
+	c := NewLRUCache(1024)
+
+	if _, ok := c.Get("missing"); ok {
+		t.Fatalf("expected miss on empty cache")
+	}
+	c.Set("key", []byte("value"))
+	if _, ok := c.Get("key"); !ok {
+		t.Fatalf("expected hit after Set")
+	}
+
+	stats := c.Stats()
+	if stats.Misses != 1 {
+		t.Errorf("Misses = %d, want 1", stats.Misses)
+	}
+	if stats.Hits != 1 {
+		t.Errorf("Hits = %d, want 1", stats.Hits)
+	}
+}
@@ -38,12 +38,26 @@ func GetDocsContent(url string, indentLevel int) (string, error) {
 		docToken = node.ObjToken
 	}
 
+	// a chapter referenced from multiple parents (OnePage mode) or fetched
+	// more than once across a run has its content fetched from the Feishu API
+	// only once; the wiki node lookup above still runs on every call.
+	cacheKey := domain + "/" + docType + "/" + docToken
+	cache := getDocsCache(ctx)
+	if cached, ok := cache.Get(cacheKey); ok {
+		return string(cached), nil
+	}
+
 	docx, blocks, err := client.GetDocxContent(ctx, docToken)
 	utils.CheckErr(err)
 
 	parser := NewParser(ctx)
 
-	markdown := parser.ParseDocxContent(docx, blocks, indentLevel)
+	markdown, err := parser.ParseDocxContent(docx, blocks, indentLevel)
+	if err != nil {
+		return "", err
+	}
+	content := markdown[1:]
 
-	return markdown[1:], nil
+	cache.Set(cacheKey, []byte(content))
+	return content, nil
 }
@@ -0,0 +1,200 @@
+package core
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chyroc/lark"
+)
+
+// feedDescriptionLength is how many characters of the stripped markdown body
+// are kept as a feed item's description.
+const feedDescriptionLength = 280
+
+// FeedItem is one exported document rendered as a feed entry.
+type FeedItem struct {
+	Title       string
+	Link        string
+	Description string
+	Published   time.Time
+	Updated     time.Time
+}
+
+// FeedBuilder accumulates FeedItems across a batch export and writes them out
+// as an RSS 2.0 or Atom 1.0 feed, so a folder/wiki-tree export can ship a
+// feed.xml alongside the converted documents.
+type FeedBuilder struct {
+	Title       string
+	Link        string
+	Description string
+	items       []FeedItem
+}
+
+// NewFeedBuilder returns a FeedBuilder for a feed titled title, linking back
+// to link (typically the root wiki/folder URL), with description used as
+// the RSS channel description / Atom subtitle.
+func NewFeedBuilder(title, link, description string) *FeedBuilder {
+	return &FeedBuilder{Title: title, Link: link, Description: description}
+}
+
+// AddItem turns one exported document into a FeedItem: title from the
+// top-level Page block, link to the original Feishu URL, publish/update time
+// from the docx metadata, and a description built from the first
+// feedDescriptionLength characters of the stripped markdown body. body is the
+// already-rendered output from ParseDocxContent for this doc; AddItem must
+// not re-render it, since ParseDocxBlockPage has the side effect of
+// appending to p.ImgTokens and a second pass would register every image in
+// the document twice.
+func (fb *FeedBuilder) AddItem(p *Parser, doc *lark.DocxDocument, url, body string) {
+	entryBlock := p.blockMap[doc.DocumentID]
+	title := ""
+	if entryBlock != nil {
+		title = strings.TrimSpace(p.ParseDocxBlockText(entryBlock.Page))
+	}
+
+	fb.items = append(fb.items, FeedItem{
+		Title:       title,
+		Link:        url,
+		Description: stripMarkdown(body, feedDescriptionLength),
+		Published:   docTimestamp(doc.CreateTime),
+		Updated:     docTimestamp(doc.EditTime),
+	})
+}
+
+// docTimestamp parses a Feishu API unix-seconds-as-string timestamp,
+// returning the zero Time if ts is empty or malformed.
+func docTimestamp(ts string) time.Time {
+	if ts == "" {
+		return time.Time{}
+	}
+	seconds, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0).UTC()
+}
+
+// stripMarkdown removes the most common Markdown/wiki markup characters and
+// truncates to at most n runes, so feed readers get plain prose.
+func stripMarkdown(s string, n int) string {
+	replacer := strings.NewReplacer(
+		"#", "", "*", "", "_", "", "`", "", ">", "",
+		"[", "", "]", "", "(", "", ")", "",
+	)
+	s = replacer.Replace(s)
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) > n {
+		runes = runes[:n]
+	}
+	return string(runes)
+}
+
+// rssFeed / rssItem mirror the subset of the RSS 2.0 schema this package
+// emits.
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	PubDate     string `xml:"pubDate,omitempty"`
+}
+
+// WriteRSS writes the accumulated items as an RSS 2.0 document.
+func (fb *FeedBuilder) WriteRSS(w io.Writer) error {
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{Title: fb.Title, Link: fb.Link, Description: fb.Description},
+	}
+	for _, item := range fb.items {
+		rssI := rssItem{Title: item.Title, Link: item.Link, Description: item.Description}
+		if !item.Published.IsZero() {
+			rssI.PubDate = item.Published.Format(time.RFC1123Z)
+		}
+		feed.Channel.Items = append(feed.Channel.Items, rssI)
+	}
+	return writeXML(w, feed)
+}
+
+// atomFeed / atomEntry mirror the subset of the Atom 1.0 schema this package
+// emits.
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Link     atomLink    `xml:"link"`
+	Updated  string      `xml:"updated"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID      string   `xml:"id"`
+	Title   string   `xml:"title"`
+	Link    atomLink `xml:"link"`
+	Summary string   `xml:"summary"`
+	Updated string   `xml:"updated"`
+}
+
+// WriteAtom writes the accumulated items as an Atom 1.0 document.
+func (fb *FeedBuilder) WriteAtom(w io.Writer) error {
+	feed := atomFeed{
+		Xmlns:    "http://www.w3.org/2005/Atom",
+		ID:       fb.Link,
+		Title:    fb.Title,
+		Subtitle: fb.Description,
+		Link:     atomLink{Href: fb.Link},
+		Updated:  time.Now().UTC().Format(time.RFC3339),
+	}
+	for _, item := range fb.items {
+		updated := item.Updated
+		if updated.IsZero() {
+			updated = item.Published
+		}
+		entry := atomEntry{
+			ID:      item.Link,
+			Title:   item.Title,
+			Link:    atomLink{Href: item.Link},
+			Summary: item.Description,
+		}
+		if !updated.IsZero() {
+			entry.Updated = updated.Format(time.RFC3339)
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return writeXML(w, feed)
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("encode feed: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,22 @@
+package core
+
+import "context"
+
+// DownloadImage fetches the raw bytes for an image block's token through
+// client, memoizing the result in the shared image cache so a token
+// referenced more than once in an export (e.g. the same image reused across
+// chapters in OnePage mode) is only downloaded once.
+func DownloadImage(ctx context.Context, client *Client, token string) ([]byte, error) {
+	cache := getImageCache(ctx)
+	if cached, ok := cache.Get(token); ok {
+		return cached, nil
+	}
+
+	data, err := client.DownloadImage(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.Set(token, data)
+	return data, nil
+}
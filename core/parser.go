@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"runtime"
 	"strings"
+	"sync"
 
 	"github.com/Wsine/feishu2md/utils"
 	"github.com/chyroc/lark"
@@ -12,18 +14,40 @@ import (
 )
 
 type Parser struct {
-	ctx       context.Context
-	ImgTokens []string
-	blockMap  map[string]*lark.DocxBlock
-	OnePage   bool
+	ctx            context.Context
+	ImgTokens      []string
+	blockMap       map[string]*lark.DocxBlock
+	OnePage        bool
+	renderer       Renderer
+	chapterContent map[string]string
+	// resolvedChapterURLs is the set of chapter-link URLs that
+	// prefetchChapterLinks actually fetched into this export. A link whose
+	// URL merely has the Feishu/Wiki shape but isn't in this set was never
+	// pulled in (e.g. OnePage is off, or it's an in-body link to a sibling
+	// doc that wasn't prefetched) and must render as an ordinary external
+	// link, not a dead internal chapter reference.
+	resolvedChapterURLs map[string]bool
+	// fetchChapter fetches one chapter link's content; it's a field rather
+	// than a direct call to GetDocsContent so tests can stub out the
+	// network/config dependency to exercise prefetchChapterLinks' worker
+	// pool and cancellation handling in isolation.
+	fetchChapter func(url string, indentLevel int) (string, error)
 }
 
 func NewParser(ctx context.Context, onePage bool) *Parser {
+	format := ""
+	if output := ctx.Value("output"); output != nil {
+		format = output.(OutputConfig).Format
+	}
 	return &Parser{
-		ctx:       ctx,
-		ImgTokens: make([]string, 0),
-		blockMap:  make(map[string]*lark.DocxBlock),
-		OnePage:   onePage,
+		ctx:                 ctx,
+		ImgTokens:           make([]string, 0),
+		blockMap:            make(map[string]*lark.DocxBlock),
+		OnePage:             onePage,
+		renderer:            NewRenderer(format),
+		chapterContent:      make(map[string]string),
+		resolvedChapterURLs: make(map[string]bool),
+		fetchChapter:        GetDocsContent,
 	}
 }
 
@@ -119,13 +143,134 @@ func renderMarkdownTable(data [][]string) string {
 // Parse the new version of document (docx)
 // =============================================================
 
-func (p *Parser) ParseDocxContent(doc *lark.DocxDocument, blocks []*lark.DocxBlock) string {
+func (p *Parser) ParseDocxContent(doc *lark.DocxDocument, blocks []*lark.DocxBlock, indentLevel int) (string, error) {
 	for _, block := range blocks {
 		p.blockMap[block.BlockID] = block
 	}
 
+	if p.OnePage {
+		if err := p.prefetchChapterLinks(); err != nil {
+			return "", err
+		}
+	}
+
 	entryBlock := p.blockMap[doc.DocumentID]
-	return p.ParseDocxBlock(entryBlock, 0)
+	return p.ParseDocxBlock(entryBlock, indentLevel), nil
+}
+
+// prefetchChapterLinks enumerates every heading block whose text is a
+// chapter link and fetches them concurrently through a bounded worker pool,
+// so the later sequential assembly pass in ParseDocxBlock can read the
+// results straight out of p.chapterContent instead of blocking on the
+// network once per heading. It honors ctx cancellation and returns the
+// first fetch error encountered; the document tree's final render order is
+// unaffected since assembly still walks blocks in their original order.
+func (p *Parser) prefetchChapterLinks() error {
+	type chapterRef struct {
+		blockID string
+		url     string
+	}
+	var refs []chapterRef
+	for _, block := range p.blockMap {
+		bText := headingTextOf(block)
+		if bText == nil {
+			continue
+		}
+		content := p.ParseDocxBlockText(bText)
+		if p.IsChapterLink(content) {
+			refs = append(refs, chapterRef{blockID: block.BlockID, url: p.GetChapterLinkUrl(content)})
+		}
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+
+	workers := runtime.NumCPU()
+	if output := p.ctx.Value("output"); output != nil {
+		if cfg, ok := output.(OutputConfig); ok && cfg.ChapterFetchWorkers > 0 {
+			workers = cfg.ChapterFetchWorkers
+		}
+	}
+	if workers > len(refs) {
+		workers = len(refs)
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, workers)
+		firstErr error
+	)
+
+	var cancelErr error
+	for _, ref := range refs {
+		select {
+		case <-p.ctx.Done():
+			cancelErr = p.ctx.Err()
+		default:
+		}
+		// Stop starting new fetches once cancelled, but fall through to
+		// wg.Wait() below rather than returning immediately: the caller
+		// reads p.chapterContent right after this function returns, with
+		// no locking of its own, so every goroutine already spawned must
+		// finish writing before we hand control back.
+		if cancelErr != nil {
+			break
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(ref chapterRef) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			content, err := p.fetchChapter(ref.url, 0)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			p.chapterContent[ref.blockID] = content
+			p.resolvedChapterURLs[ref.url] = true
+		}(ref)
+	}
+	wg.Wait()
+
+	if cancelErr != nil {
+		return cancelErr
+	}
+	return firstErr
+}
+
+// headingTextOf returns the *lark.DocxBlockText carried by a heading block,
+// or nil if b is not a heading.
+func headingTextOf(b *lark.DocxBlock) *lark.DocxBlockText {
+	switch b.BlockType {
+	case lark.DocxBlockTypeHeading1:
+		return b.Heading1
+	case lark.DocxBlockTypeHeading2:
+		return b.Heading2
+	case lark.DocxBlockTypeHeading3:
+		return b.Heading3
+	case lark.DocxBlockTypeHeading4:
+		return b.Heading4
+	case lark.DocxBlockTypeHeading5:
+		return b.Heading5
+	case lark.DocxBlockTypeHeading6:
+		return b.Heading6
+	case lark.DocxBlockTypeHeading7:
+		return b.Heading7
+	case lark.DocxBlockTypeHeading8:
+		return b.Heading8
+	case lark.DocxBlockTypeHeading9:
+		return b.Heading9
+	default:
+		return nil
+	}
 }
 
 func (p *Parser) ParseDocxBlock(b *lark.DocxBlock, indentLevel int) string {
@@ -137,23 +282,23 @@ func (p *Parser) ParseDocxBlock(b *lark.DocxBlock, indentLevel int) string {
 	case lark.DocxBlockTypeText:
 		buf.WriteString(p.ParseDocxBlockText(b.Text))
 	case lark.DocxBlockTypeHeading1:
-		buf.WriteString(p.processHeadingWithLink(b.Heading1, "#"))
+		buf.WriteString(p.processHeadingWithLink(b.BlockID, b.Heading1, 1))
 	case lark.DocxBlockTypeHeading2:
-		buf.WriteString(p.processHeadingWithLink(b.Heading2, "##"))
+		buf.WriteString(p.processHeadingWithLink(b.BlockID, b.Heading2, 2))
 	case lark.DocxBlockTypeHeading3:
-		buf.WriteString(p.processHeadingWithLink(b.Heading3, "###"))
+		buf.WriteString(p.processHeadingWithLink(b.BlockID, b.Heading3, 3))
 	case lark.DocxBlockTypeHeading4:
-		buf.WriteString(p.processHeadingWithLink(b.Heading4, "####"))
+		buf.WriteString(p.processHeadingWithLink(b.BlockID, b.Heading4, 4))
 	case lark.DocxBlockTypeHeading5:
-		buf.WriteString(p.processHeadingWithLink(b.Heading5, "#####"))
+		buf.WriteString(p.processHeadingWithLink(b.BlockID, b.Heading5, 5))
 	case lark.DocxBlockTypeHeading6:
-		buf.WriteString(p.processHeadingWithLink(b.Heading6, "######"))
+		buf.WriteString(p.processHeadingWithLink(b.BlockID, b.Heading6, 6))
 	case lark.DocxBlockTypeHeading7:
-		buf.WriteString(p.processHeadingWithLink(b.Heading7, "#######"))
+		buf.WriteString(p.processHeadingWithLink(b.BlockID, b.Heading7, 7))
 	case lark.DocxBlockTypeHeading8:
-		buf.WriteString(p.processHeadingWithLink(b.Heading8, "########"))
+		buf.WriteString(p.processHeadingWithLink(b.BlockID, b.Heading8, 8))
 	case lark.DocxBlockTypeHeading9:
-		buf.WriteString(p.processHeadingWithLink(b.Heading9, "#########"))
+		buf.WriteString(p.processHeadingWithLink(b.BlockID, b.Heading9, 9))
 	case lark.DocxBlockTypeBullet:
 		buf.WriteString(p.ParseDocxBlockBullet(b, indentLevel))
 	case lark.DocxBlockTypeOrdered:
@@ -161,21 +306,13 @@ func (p *Parser) ParseDocxBlock(b *lark.DocxBlock, indentLevel int) string {
 	case lark.DocxBlockTypeCode:
 		buf.WriteString(p.ParseCodeBlock(b, indentLevel))
 	case lark.DocxBlockTypeQuote:
-		buf.WriteString("> ")
-		buf.WriteString(p.ParseDocxBlockText(b.Quote))
+		buf.WriteString(p.renderer.Quote(p.ParseDocxBlockText(b.Quote)))
 	case lark.DocxBlockTypeEquation:
-		buf.WriteString("$$\n")
-		buf.WriteString(p.ParseDocxBlockText(b.Equation))
-		buf.WriteString("\n$$\n")
+		buf.WriteString(p.renderer.EquationBlock(p.ParseDocxBlockText(b.Equation)))
 	case lark.DocxBlockTypeTodo:
-		if b.Todo.Style.Done {
-			buf.WriteString("- [x] ")
-		} else {
-			buf.WriteString("- [ ] ")
-		}
-		buf.WriteString(p.ParseDocxBlockText(b.Todo))
+		buf.WriteString(p.renderer.Todo(b.Todo.Style.Done, p.ParseDocxBlockText(b.Todo)))
 	case lark.DocxBlockTypeDivider:
-		buf.WriteString("---\n")
+		buf.WriteString(p.renderer.Divider())
 	case lark.DocxBlockTypeImage:
 		buf.WriteString(p.ParseDocxBlockImage(b.Image))
 	case lark.DocxBlockTypeTableCell:
@@ -189,42 +326,36 @@ func (p *Parser) ParseDocxBlock(b *lark.DocxBlock, indentLevel int) string {
 	return buf.String()
 }
 
-func (p *Parser) processHeadingWithLink(bText *lark.DocxBlockText, headingPrefix string) string {
+func (p *Parser) processHeadingWithLink(blockID string, bText *lark.DocxBlockText, level int) string {
 	headingContent := p.ParseDocxBlockText(bText)
 
 	// if is a chapter link, load the chapter content
 	if p.OnePage && p.IsChapterLink(headingContent) {
+		if chapterContent, ok := p.chapterContent[blockID]; ok {
+			return p.renderer.Heading(level, chapterContent)
+		}
+		// not prefetched (e.g. ParseDocxBlock called without
+		// ParseDocxContent's prefetch pass); fall back to a synchronous fetch.
 		chapterUrl := p.GetChapterLinkUrl(headingContent)
-		chapterContent, err := GetDocsContent(chapterUrl)
+		chapterContent, err := GetDocsContent(chapterUrl, 0)
 		if err != nil {
 			return ""
 		}
-		return headingPrefix + " " + chapterContent
+		return p.renderer.Heading(level, chapterContent)
 	}
-	return headingPrefix + " " + headingContent
+	return p.renderer.Heading(level, headingContent)
 }
 
 func (p *Parser) ParseCodeBlock(b *lark.DocxBlock, indentLevel int) string {
 	code := p.ParseDocxBlockText(b.Code)
 	codeLanguage := DocxCodeLang2MdStr[b.Code.Style.Language]
-	indentUnit := strings.Repeat("\t", indentLevel)
-
-	buf := new(strings.Builder)
-	buf.WriteString("\n")
-	buf.WriteString(indentUnit + "```" + codeLanguage)
-	buf.WriteString("\n")
-	buf.WriteString(indentUnit + code)
-	buf.WriteString(indentUnit + "```")
-	buf.WriteString("\n")
-
-	return buf.String()
+	return p.renderer.CodeBlock(codeLanguage, code, indentLevel)
 }
 
 func (p *Parser) ParseDocxBlockPage(b *lark.DocxBlock) string {
 	buf := new(strings.Builder)
 
-	buf.WriteString("# ")
-	buf.WriteString(p.ParseDocxBlockText(b.Page))
+	buf.WriteString(p.renderer.Heading(1, p.ParseDocxBlockText(b.Page)))
 	buf.WriteString("\n")
 
 	for _, childId := range b.Children {
@@ -238,29 +369,22 @@ func (p *Parser) ParseDocxBlockPage(b *lark.DocxBlock) string {
 
 func (p *Parser) ParseDocxBlockText(b *lark.DocxBlockText) string {
 	buf := new(strings.Builder)
+	boldPrefix, boldSuffix := p.renderer.BoldDelim()
 	numElem := len(b.Elements)
 	for i := 0; i < numElem; i++ {
 		inline := numElem > 1
 		currentText := p.ParseDocxTextElement(b.Elements[i], inline)
 
-		if strings.HasPrefix(currentText, "**") && strings.HasSuffix(currentText, "**") {
-			trimmedText := strings.TrimPrefix(currentText, "**")
-			trimmedText = strings.TrimSuffix(trimmedText, "**")
-			trimmedText = strings.TrimSpace(trimmedText)
-			currentText = "**" + trimmedText + "**"
+		if p.elementIsBold(b.Elements[i]) {
+			asBold, _ := stripDelim(currentText, boldPrefix, boldSuffix)
+			currentText = boldPrefix + strings.TrimSpace(asBold) + boldSuffix
 
-			for i+1 < numElem {
+			for i+1 < numElem && p.elementIsBold(b.Elements[i+1]) {
 				nextText := p.ParseDocxTextElement(b.Elements[i+1], inline)
-				if strings.HasPrefix(nextText, "**") && strings.HasSuffix(nextText, "**") {
-					nextTrimmed := strings.TrimPrefix(nextText, "**")
-					nextTrimmed = strings.TrimSuffix(nextTrimmed, "**")
-					nextTrimmed = strings.TrimSpace(nextTrimmed)
-
-					currentText = strings.TrimSuffix(currentText, "**") + nextTrimmed + "**"
-					i++
-				} else {
-					break
-				}
+				nextBold, _ := stripDelim(nextText, boldPrefix, boldSuffix)
+				currentText = strings.TrimSuffix(currentText, boldSuffix) +
+					strings.TrimSpace(nextBold) + boldSuffix
+				i++
 			}
 		}
 
@@ -271,6 +395,37 @@ func (p *Parser) ParseDocxBlockText(b *lark.DocxBlockText) string {
 	return buf.String()
 }
 
+// elementIsBold reports whether e renders as a bold run through
+// Renderer.Bold, i.e. whether the adjacent-bold merge above may treat it as
+// mergeable. This is driven by the element's own style rather than by
+// re-sniffing the rendered text for the format's bold delimiter: a plain
+// run whose content happens to start and end with the delimiter character
+// (e.g. a literal "*" used for a footnote or multiplication in prose) must
+// not be mistaken for a bold run.
+func (p *Parser) elementIsBold(e *lark.DocxTextElement) bool {
+	if e.TextRun == nil || e.TextRun.TextElementStyle == nil || !e.TextRun.TextElementStyle.Bold {
+		return false
+	}
+	useHTMLTags := NewConfig("", "").Output.UseHTMLTags
+	if p.ctx.Value("output") != nil {
+		useHTMLTags = p.ctx.Value("output").(OutputConfig).UseHTMLTags
+	}
+	// Bold HTML-tagged output ("<strong>...</strong>") isn't wrapped in the
+	// renderer's bold delimiter, so it's never a candidate for this merge.
+	return !useHTMLTags
+}
+
+// stripDelim reports whether text is wrapped in prefix/suffix and, if so,
+// returns the text with that wrapping removed.
+func stripDelim(text, prefix, suffix string) (string, bool) {
+	if !strings.HasPrefix(text, prefix) || !strings.HasSuffix(text, suffix) {
+		return "", false
+	}
+	inner := strings.TrimPrefix(text, prefix)
+	inner = strings.TrimSuffix(inner, suffix)
+	return inner, true
+}
+
 // IsChapterLink checks if the string is a feishu other chapter link
 // eg: [Chapter 1](https://xxx.feishu.cn/docx/xxx)
 func (p *Parser) IsChapterLink(link string) bool {
@@ -329,11 +484,7 @@ func (p *Parser) ParseDocxTextElement(e *lark.DocxTextElement, inline bool) stri
 			fmt.Sprintf("[%s](%s)", e.MentionDoc.Title, utils.UnescapeURL(e.MentionDoc.URL)))
 	}
 	if e.Equation != nil {
-		symbol := "$$"
-		if inline {
-			symbol = "$"
-		}
-		buf.WriteString(symbol + strings.TrimSuffix(e.Equation.Content, "\n") + symbol)
+		buf.WriteString(p.renderer.Equation(e.Equation.Content, inline))
 	}
 	return buf.String()
 }
@@ -341,7 +492,15 @@ func (p *Parser) ParseDocxTextElement(e *lark.DocxTextElement, inline bool) stri
 func (p *Parser) ParseDocxTextElementTextRun(tr *lark.DocxTextElementTextRun) string {
 	buf := new(strings.Builder)
 	postWrite := ""
-	if style := tr.TextElementStyle; style != nil {
+	content := tr.Content
+	style := tr.TextElementStyle
+	// an explicit link/inline-code style already carries its own semantics,
+	// so only plain and bold/italic/strikethrough/underline runs are eligible
+	// for auto-linkification.
+	if style == nil || (!style.InlineCode && style.Link == nil) {
+		content = p.autoLinkify(content)
+	}
+	if style != nil {
 		useHTMLTags := NewConfig("", "").Output.UseHTMLTags
 		if p.ctx.Value("output") != nil {
 			useHTMLTags = p.ctx.Value("output").(OutputConfig).UseHTMLTags
@@ -351,47 +510,45 @@ func (p *Parser) ParseDocxTextElementTextRun(tr *lark.DocxTextElementTextRun) st
 				buf.WriteString("<strong>")
 				postWrite = "</strong>"
 			} else {
-				buf.WriteString("**")
-				postWrite = "**"
+				return p.renderer.Bold(content)
 			}
 		} else if style.Italic {
 			if useHTMLTags {
 				buf.WriteString("<em>")
 				postWrite = "</em>"
 			} else {
-				buf.WriteString("_")
-				postWrite = "_"
+				return p.renderer.Italic(content)
 			}
 		} else if style.Strikethrough {
 			if useHTMLTags {
 				buf.WriteString("<del>")
 				postWrite = "</del>"
 			} else {
-				buf.WriteString("~~")
-				postWrite = "~~"
+				return p.renderer.Strikethrough(content)
 			}
 		} else if style.Underline {
-			buf.WriteString("<u>")
-			postWrite = "</u>"
+			return p.renderer.Underline(content)
 		} else if style.InlineCode {
-			buf.WriteString("`")
-			postWrite = "`"
+			return p.renderer.InlineCode(tr.Content)
 		} else if link := style.Link; link != nil {
-			buf.WriteString("[")
-			postWrite = fmt.Sprintf("](%s)", utils.UnescapeURL(link.URL))
+			url := utils.UnescapeURL(link.URL)
+			if p.OnePage && p.resolvedChapterURLs[url] {
+				// a link to another Feishu doc/wiki page that was actually
+				// prefetched into this export, i.e. a chapter reference,
+				// rather than an arbitrary external URL.
+				return p.renderer.ChapterLink(tr.Content, url)
+			}
+			return p.renderer.Link(tr.Content, url)
 		}
 	}
-	buf.WriteString(tr.Content)
+	buf.WriteString(content)
 	buf.WriteString(postWrite)
 	return buf.String()
 }
 
 func (p *Parser) ParseDocxBlockImage(img *lark.DocxBlockImage) string {
-	buf := new(strings.Builder)
-	buf.WriteString(fmt.Sprintf("![](%s)", img.Token))
-	buf.WriteString("\n")
 	p.ImgTokens = append(p.ImgTokens, img.Token)
-	return buf.String()
+	return p.renderer.Image(img.Token)
 }
 
 func (p *Parser) ParseDocxWhatever(body *lark.DocBody) string {
@@ -403,8 +560,7 @@ func (p *Parser) ParseDocxWhatever(body *lark.DocBody) string {
 func (p *Parser) ParseDocxBlockBullet(b *lark.DocxBlock, indentLevel int) string {
 	buf := new(strings.Builder)
 
-	buf.WriteString("- ")
-	buf.WriteString(p.ParseDocxBlockText(b.Bullet))
+	buf.WriteString(p.renderer.Bullet(p.ParseDocxBlockText(b.Bullet), indentLevel))
 
 	for _, childId := range b.Children {
 		childBlock := p.blockMap[childId]
@@ -433,8 +589,7 @@ func (p *Parser) ParseDocxBlockOrdered(b *lark.DocxBlock, indentLevel int) strin
 		}
 	}
 
-	buf.WriteString(fmt.Sprintf("%d. ", order))
-	buf.WriteString(p.ParseDocxBlockText(b.Ordered))
+	buf.WriteString(p.renderer.Ordered(order, p.ParseDocxBlockText(b.Ordered), indentLevel))
 
 	for _, childId := range b.Children {
 		childBlock := p.blockMap[childId]
@@ -472,7 +627,7 @@ func (p *Parser) ParseDocxBlockTable(t *lark.DocxBlockTable) string {
 	}
 
 	buf := new(strings.Builder)
-	buf.WriteString(renderMarkdownTable(rows))
+	buf.WriteString(p.renderer.Table(rows))
 	buf.WriteString("\n")
 	return buf.String()
 }
@@ -482,8 +637,7 @@ func (p *Parser) ParseDocxBlockQuoteContainer(b *lark.DocxBlock) string {
 
 	for _, child := range b.Children {
 		block := p.blockMap[child]
-		buf.WriteString("> ")
-		buf.WriteString(p.ParseDocxBlock(block, 0))
+		buf.WriteString(p.renderer.Quote(p.ParseDocxBlock(block, 0)))
 	}
 
 	return buf.String()
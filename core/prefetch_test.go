@@ -0,0 +1,119 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chyroc/lark"
+)
+
+// chapterLinkHeading returns a Heading1 block whose text is a chapter link
+// to url, matching the shape prefetchChapterLinks looks for.
+func chapterLinkHeading(blockID, url string) *lark.DocxBlock {
+	return &lark.DocxBlock{
+		BlockID:   blockID,
+		BlockType: lark.DocxBlockTypeHeading1,
+		Heading1: &lark.DocxBlockText{
+			Elements: []*lark.DocxTextElement{
+				{
+					TextRun: &lark.DocxTextElementTextRun{
+						Content: "Chapter",
+						TextElementStyle: &lark.DocxTextElementStyle{
+							Link: &lark.DocxTextElementStyleLink{URL: url},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// TestPrefetchChapterLinksWaitsForInFlightFetchesOnCancellation exercises the
+// fix for the concurrent map read/write race: cancelling mid-loop must stop
+// *new* fetches from starting, but every goroutine already spawned must
+// finish writing to p.chapterContent before prefetchChapterLinks returns.
+func TestPrefetchChapterLinksWaitsForInFlightFetchesOnCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = context.WithValue(ctx, "output", OutputConfig{ChapterFetchWorkers: 1})
+	p := NewParser(ctx, true)
+
+	urls := []string{
+		"https://a.feishu.cn/docx/one",
+		"https://a.feishu.cn/docx/two",
+		"https://a.feishu.cn/docx/three",
+	}
+	for i, url := range urls {
+		block := chapterLinkHeading(fmt.Sprintf("h%d", i), url)
+		p.blockMap[block.BlockID] = block
+	}
+
+	var callCount int32
+	firstStarted := make(chan struct{})
+	firstRelease := make(chan struct{})
+	p.fetchChapter = func(url string, indentLevel int) (string, error) {
+		if atomic.AddInt32(&callCount, 1) == 1 {
+			close(firstStarted)
+			<-firstRelease
+		}
+		return "content for " + url, nil
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- p.prefetchChapterLinks() }()
+
+	// With exactly one worker, the first fetch is running and the loop is
+	// blocked trying to start the second. Cancel now, then let the first
+	// fetch complete: the loop should observe the cancellation on its next
+	// iteration and stop spawning further fetches, without losing the
+	// result already written by the in-flight one.
+	<-firstStarted
+	cancel()
+	close(firstRelease)
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("prefetchChapterLinks() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("prefetchChapterLinks did not return after cancellation")
+	}
+
+	if got := len(p.chapterContent); got != int(atomic.LoadInt32(&callCount)) {
+		t.Errorf("chapterContent has %d entries, want one per completed fetch (%d)", got, callCount)
+	}
+	if got := atomic.LoadInt32(&callCount); got >= int32(len(urls)) {
+		t.Errorf("fetchChapter was called %d times, want fewer than %d (cancellation should stop new fetches)", got, len(urls))
+	}
+}
+
+func TestPrefetchChapterLinksReturnsFirstFetchError(t *testing.T) {
+	p := NewParser(context.Background(), true)
+	block := chapterLinkHeading("h0", "https://a.feishu.cn/docx/one")
+	p.blockMap[block.BlockID] = block
+
+	wantErr := errors.New("boom")
+	p.fetchChapter = func(url string, indentLevel int) (string, error) {
+		return "", wantErr
+	}
+
+	if err := p.prefetchChapterLinks(); !errors.Is(err, wantErr) {
+		t.Errorf("prefetchChapterLinks() error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestPrefetchChapterLinksNoOpWithoutChapterLinks(t *testing.T) {
+	p := NewParser(context.Background(), true)
+	p.fetchChapter = func(url string, indentLevel int) (string, error) {
+		t.Fatalf("fetchChapter should not be called when there are no chapter links")
+		return "", nil
+	}
+
+	if err := p.prefetchChapterLinks(); err != nil {
+		t.Errorf("prefetchChapterLinks() = %v, want nil", err)
+	}
+}
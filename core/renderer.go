@@ -0,0 +1,138 @@
+package core
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Renderer decouples the block traversal in Parser from the concrete output
+// syntax. Each ParseDocxBlock* method delegates the actual text formatting to
+// a Renderer implementation, so a new output format only needs a new
+// Renderer, not changes to the block dispatch in parser.go.
+type Renderer interface {
+	// Heading renders a heading at the given level (1-9) wrapping inline.
+	Heading(level int, inline string) string
+	// CodeBlock renders a code block. The Markdown and Org renderers indent
+	// it by indent tab stops; the MediaWiki renderer ignores indent.
+	CodeBlock(lang, code string, indent int) string
+	// Table renders a table, rows[0] being the header row.
+	Table(rows [][]string) string
+	Bullet(content string, indent int) string
+	Ordered(order int, content string, indent int) string
+	Todo(done bool, content string) string
+	Quote(content string) string
+	Divider() string
+	Image(token string) string
+	Equation(content string, inline bool) string
+	// EquationBlock renders a standalone Equation block (as opposed to an
+	// inline equation within a text run).
+	EquationBlock(content string) string
+	Link(text, url string) string
+	// ChapterLink renders a link to another chapter that has been fetched
+	// into the same export, given its resolved title. Most formats treat it
+	// the same as an ordinary Link; MediaWiki renders it as an internal
+	// `[[Title]]` wiki-link instead of an external `[url text]` one.
+	ChapterLink(title, url string) string
+	// BoldDelim returns the prefix/suffix Bold wraps its text in, so callers
+	// that need to detect or merge already-rendered bold runs (e.g. the
+	// adjacent-bold merge in ParseDocxBlockText) don't have to hardcode a
+	// specific format's syntax.
+	BoldDelim() (prefix, suffix string)
+	Bold(text string) string
+	Italic(text string) string
+	Strikethrough(text string) string
+	Underline(text string) string
+	InlineCode(text string) string
+}
+
+// NewRenderer returns the Renderer for the given output format. It defaults
+// to the Markdown renderer for an empty or unrecognized format so existing
+// configs keep working unchanged.
+func NewRenderer(format string) Renderer {
+	switch format {
+	case "org":
+		return &OrgRenderer{}
+	case "mediawiki":
+		return &MediaWikiRenderer{}
+	default:
+		return &MarkdownRenderer{}
+	}
+}
+
+// MarkdownRenderer is the original, default output format.
+type MarkdownRenderer struct{}
+
+func (r *MarkdownRenderer) Heading(level int, inline string) string {
+	return strings.Repeat("#", level) + " " + inline
+}
+
+func (r *MarkdownRenderer) CodeBlock(lang, code string, indent int) string {
+	indentUnit := strings.Repeat("\t", indent)
+	buf := new(strings.Builder)
+	buf.WriteString("\n")
+	buf.WriteString(indentUnit + "```" + lang)
+	buf.WriteString("\n")
+	buf.WriteString(indentUnit + code)
+	buf.WriteString(indentUnit + "```")
+	buf.WriteString("\n")
+	return buf.String()
+}
+
+func (r *MarkdownRenderer) Table(rows [][]string) string {
+	return renderMarkdownTable(rows)
+}
+
+func (r *MarkdownRenderer) Bullet(content string, indent int) string {
+	return "- " + content
+}
+
+func (r *MarkdownRenderer) Ordered(order int, content string, indent int) string {
+	return strconv.Itoa(order) + ". " + content
+}
+
+func (r *MarkdownRenderer) Todo(done bool, content string) string {
+	if done {
+		return "- [x] " + content
+	}
+	return "- [ ] " + content
+}
+
+func (r *MarkdownRenderer) Quote(content string) string {
+	return "> " + content
+}
+
+func (r *MarkdownRenderer) Divider() string {
+	return "---\n"
+}
+
+func (r *MarkdownRenderer) Image(token string) string {
+	return "![](" + token + ")\n"
+}
+
+func (r *MarkdownRenderer) Equation(content string, inline bool) string {
+	symbol := "$$"
+	if inline {
+		symbol = "$"
+	}
+	return symbol + strings.TrimSuffix(content, "\n") + symbol
+}
+
+func (r *MarkdownRenderer) EquationBlock(content string) string {
+	return "$$\n" + content + "\n$$\n"
+}
+
+func (r *MarkdownRenderer) Link(text, url string) string {
+	return "[" + text + "](" + url + ")"
+}
+
+func (r *MarkdownRenderer) ChapterLink(title, url string) string {
+	return r.Link(title, url)
+}
+
+func (r *MarkdownRenderer) BoldDelim() (string, string) { return "**", "**" }
+
+func (r *MarkdownRenderer) Bold(text string) string          { return "**" + text + "**" }
+func (r *MarkdownRenderer) Italic(text string) string        { return "_" + text + "_" }
+func (r *MarkdownRenderer) Strikethrough(text string) string { return "~~" + text + "~~" }
+func (r *MarkdownRenderer) Underline(text string) string     { return "<u>" + text + "</u>" }
+func (r *MarkdownRenderer) InlineCode(text string) string    { return "`" + text + "`" }
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MediaWikiRenderer emits MediaWiki/DokuWiki syntax so exported Feishu docs
+// can be pasted directly into a MediaWiki instance.
+type MediaWikiRenderer struct{}
+
+func (r *MediaWikiRenderer) Heading(level int, inline string) string {
+	eq := strings.Repeat("=", level)
+	return eq + " " + inline + " " + eq
+}
+
+func (r *MediaWikiRenderer) CodeBlock(lang, code string, indent int) string {
+	buf := new(strings.Builder)
+	buf.WriteString("\n")
+	if lang == "" {
+		buf.WriteString("<pre>\n")
+		buf.WriteString(code)
+		buf.WriteString("</pre>\n")
+		return buf.String()
+	}
+	buf.WriteString(fmt.Sprintf("<syntaxhighlight lang=\"%s\">\n", lang))
+	buf.WriteString(code)
+	buf.WriteString("</syntaxhighlight>\n")
+	return buf.String()
+}
+
+func (r *MediaWikiRenderer) Table(rows [][]string) string {
+	buf := new(strings.Builder)
+	buf.WriteString("{|\n")
+	for i, row := range rows {
+		cellPrefix := "|"
+		if i == 0 {
+			cellPrefix = "!"
+		}
+		buf.WriteString(cellPrefix + " " + strings.Join(row, " "+cellPrefix+cellPrefix+" ") + "\n")
+		buf.WriteString("|-\n")
+	}
+	buf.WriteString("|}\n")
+	return buf.String()
+}
+
+func (r *MediaWikiRenderer) Bullet(content string, indent int) string {
+	return strings.Repeat("*", indent+1) + " " + content
+}
+
+func (r *MediaWikiRenderer) Ordered(order int, content string, indent int) string {
+	_ = order
+	return strings.Repeat("#", indent+1) + " " + content
+}
+
+func (r *MediaWikiRenderer) Todo(done bool, content string) string {
+	if done {
+		return "* <del>" + content + "</del>"
+	}
+	return "* " + content
+}
+
+func (r *MediaWikiRenderer) Quote(content string) string {
+	return "<blockquote>" + content + "</blockquote>\n"
+}
+
+func (r *MediaWikiRenderer) Divider() string {
+	return "----\n"
+}
+
+func (r *MediaWikiRenderer) Image(token string) string {
+	return fmt.Sprintf("[[File:%s.png]]\n", token)
+}
+
+func (r *MediaWikiRenderer) Equation(content string, inline bool) string {
+	content = strings.TrimSuffix(content, "\n")
+	return "<math>" + content + "</math>"
+}
+
+func (r *MediaWikiRenderer) EquationBlock(content string) string {
+	content = strings.TrimSuffix(content, "\n")
+	return "<math>\n" + content + "\n</math>\n"
+}
+
+// Link renders an external text+url link.
+func (r *MediaWikiRenderer) Link(text, url string) string {
+	return "[" + url + " " + text + "]"
+}
+
+// ChapterLink renders a link to another chapter already fetched into the
+// same export as an internal wiki-link.
+func (r *MediaWikiRenderer) ChapterLink(title, url string) string {
+	return "[[" + title + "]]"
+}
+
+func (r *MediaWikiRenderer) BoldDelim() (string, string) { return "'''", "'''" }
+
+func (r *MediaWikiRenderer) Bold(text string) string          { return "'''" + text + "'''" }
+func (r *MediaWikiRenderer) Italic(text string) string        { return "''" + text + "''" }
+func (r *MediaWikiRenderer) Strikethrough(text string) string { return "<s>" + text + "</s>" }
+func (r *MediaWikiRenderer) Underline(text string) string     { return "<u>" + text + "</u>" }
+func (r *MediaWikiRenderer) InlineCode(text string) string    { return "<code>" + text + "</code>" }
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"strconv"
+	"strings"
+)
+
+// OrgRenderer emits Org-mode markup following the conventions used by
+// go-org, so exported files can be opened directly in Emacs or fed to Hugo's
+// org-mode layouts.
+type OrgRenderer struct{}
+
+func (r *OrgRenderer) Heading(level int, inline string) string {
+	if level > 9 {
+		level = 9
+	}
+	return strings.Repeat("*", level) + " " + inline
+}
+
+func (r *OrgRenderer) CodeBlock(lang, code string, indent int) string {
+	indentUnit := strings.Repeat("\t", indent)
+	buf := new(strings.Builder)
+	buf.WriteString("\n")
+	buf.WriteString(indentUnit + "#+BEGIN_SRC " + lang)
+	buf.WriteString("\n")
+	buf.WriteString(indentUnit + code)
+	buf.WriteString(indentUnit + "#+END_SRC")
+	buf.WriteString("\n")
+	return buf.String()
+}
+
+func (r *OrgRenderer) Table(rows [][]string) string {
+	buf := new(strings.Builder)
+	for i, row := range rows {
+		buf.WriteString("|" + strings.Join(row, "|") + "|\n")
+		if i == 0 {
+			sep := make([]string, len(row))
+			for j, cell := range row {
+				sep[j] = strings.Repeat("-", len(cell)+2)
+			}
+			buf.WriteString("|" + strings.Join(sep, "+") + "|\n")
+		}
+	}
+	return buf.String()
+}
+
+func (r *OrgRenderer) Bullet(content string, indent int) string {
+	return "- " + content
+}
+
+func (r *OrgRenderer) Ordered(order int, content string, indent int) string {
+	return strconv.Itoa(order) + ". " + content
+}
+
+func (r *OrgRenderer) Todo(done bool, content string) string {
+	if done {
+		return "- [X] " + content
+	}
+	return "- [ ] " + content
+}
+
+func (r *OrgRenderer) Quote(content string) string {
+	return "#+BEGIN_QUOTE\n" + content + "#+END_QUOTE\n"
+}
+
+func (r *OrgRenderer) Divider() string {
+	return "-----\n"
+}
+
+func (r *OrgRenderer) Image(token string) string {
+	return "[[" + token + "]]\n"
+}
+
+func (r *OrgRenderer) Equation(content string, inline bool) string {
+	content = strings.TrimSuffix(content, "\n")
+	if inline {
+		return "$" + content + "$"
+	}
+	return "\\[" + content + "\\]"
+}
+
+func (r *OrgRenderer) EquationBlock(content string) string {
+	content = strings.TrimSuffix(content, "\n")
+	return "\\[\n" + content + "\n\\]\n"
+}
+
+func (r *OrgRenderer) Link(text, url string) string {
+	return "[[" + url + "][" + text + "]]"
+}
+
+func (r *OrgRenderer) ChapterLink(title, url string) string {
+	return r.Link(title, url)
+}
+
+func (r *OrgRenderer) BoldDelim() (string, string) { return "*", "*" }
+
+func (r *OrgRenderer) Bold(text string) string          { return "*" + text + "*" }
+func (r *OrgRenderer) Italic(text string) string        { return "/" + text + "/" }
+func (r *OrgRenderer) Strikethrough(text string) string { return "+" + text + "+" }
+func (r *OrgRenderer) Underline(text string) string     { return "_" + text + "_" }
+func (r *OrgRenderer) InlineCode(text string) string    { return "=" + text + "=" }
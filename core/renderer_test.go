@@ -0,0 +1,132 @@
+package core
+
+import "testing"
+
+// renderers is the full set of Renderer implementations, run through every
+// table-driven case below so a change to the shared Renderer interface
+// can't silently drop a format.
+var renderers = map[string]Renderer{
+	"markdown":  &MarkdownRenderer{},
+	"org":       &OrgRenderer{},
+	"mediawiki": &MediaWikiRenderer{},
+}
+
+func TestRendererHeading(t *testing.T) {
+	tests := []struct {
+		format string
+		level  int
+		want   string
+	}{
+		{"markdown", 2, "## H2"},
+		{"org", 2, "** H2"},
+		{"mediawiki", 2, "== H2 =="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got := renderers[tt.format].Heading(tt.level, "H2")
+			if got != tt.want {
+				t.Errorf("Heading(%d, %q) = %q, want %q", tt.level, "H2", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRendererBold(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"markdown", "**text**"},
+		{"org", "*text*"},
+		{"mediawiki", "'''text'''"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got := renderers[tt.format].Bold("text")
+			if got != tt.want {
+				t.Errorf("Bold(%q) = %q, want %q", "text", got, tt.want)
+			}
+
+			prefix, suffix := renderers[tt.format].BoldDelim()
+			if wrapped := prefix + "text" + suffix; wrapped != tt.want {
+				t.Errorf("BoldDelim() = (%q, %q), doesn't reconstruct Bold()'s own output %q", prefix, suffix, tt.want)
+			}
+		})
+	}
+}
+
+func TestRendererLink(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"markdown", "[text](url)"},
+		{"org", "[[url][text]]"},
+		{"mediawiki", "[url text]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got := renderers[tt.format].Link("text", "url")
+			if got != tt.want {
+				t.Errorf("Link(%q, %q) = %q, want %q", "text", "url", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRendererChapterLink(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"markdown", "[text](url)"},
+		{"org", "[[url][text]]"},
+		{"mediawiki", "[[text]]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got := renderers[tt.format].ChapterLink("text", "url")
+			if got != tt.want {
+				t.Errorf("ChapterLink(%q, %q) = %q, want %q", "text", "url", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRendererEquationBlock(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"markdown", "$$\nE=mc^2\n$$\n"},
+		{"org", "\\[\nE=mc^2\n\\]\n"},
+		{"mediawiki", "<math>\nE=mc^2\n</math>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got := renderers[tt.format].EquationBlock("E=mc^2")
+			if got != tt.want {
+				t.Errorf("EquationBlock(%q) = %q, want %q", "E=mc^2", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRendererDivider(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"markdown", "---\n"},
+		{"org", "-----\n"},
+		{"mediawiki", "----\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got := renderers[tt.format].Divider()
+			if got != tt.want {
+				t.Errorf("Divider() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}